@@ -17,13 +17,10 @@ limitations under the License.
 package main
 
 import (
-	"bufio"
 	"encoding/base64"
 	"errors"
 	"fmt"
-	"os"
-
-	"strings"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/util"
@@ -37,16 +34,63 @@ import (
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
 	"golang.org/x/net/context"
+
+	"github.com/hyperledger/fabric/examples/chaincode/go/asset_management_interactive/app3/assetprovider"
 )
 
 var (
 	confidentialityOn bool
 
 	confidentialityLevel pb.ConfidentialityLevel
-	chaincodeName        string
 	user                 string
+
+	// assetProvider is the inventory source readAssets loaded assets/lotNums
+	// from, selected via assets.provider.
+	assetProvider assetprovider.AssetProvider
+
+	// chaincodeNameMu guards chaincodeName: deployInternal sets it once the
+	// deploy transaction has been submitted, whatever the peer's response,
+	// and every assign/transfer/query
+	// helper reads it to address that chaincode. Now that httpapi.Server
+	// serves each HTTP request on its own goroutine, a deploy racing one of
+	// those reads would otherwise be a plain, unsynchronized string race.
+	chaincodeNameMu sync.RWMutex
+	chaincodeName   string
+
+	// assetsMu guards the package-level assets/lotNums: readAssets's initial
+	// load and assetProvider.Watch's reload callback (fired from a
+	// background poller for e.g. HTTPProvider) both write them, and any
+	// future reader - such as an httpapi endpoint listing the current
+	// inventory - would otherwise race with either.
+	assetsMu sync.RWMutex
 )
 
+// getChaincodeName returns the name deployInternal deployed the chaincode
+// under, or "" if deploy hasn't run yet.
+func getChaincodeName() string {
+	chaincodeNameMu.RLock()
+	defer chaincodeNameMu.RUnlock()
+	return chaincodeName
+}
+
+// setChaincodeName records the name deployInternal deployed the chaincode
+// under.
+func setChaincodeName(name string) {
+	chaincodeNameMu.Lock()
+	defer chaincodeNameMu.Unlock()
+	chaincodeName = name
+}
+
+// setInventory replaces the current assets/lotNums with newAssets/newLotNums,
+// the only safe way to update them once assetProvider.Watch may be reloading
+// them concurrently.
+func setInventory(newAssets map[string]string, newLotNums []string) {
+	assetsMu.Lock()
+	defer assetsMu.Unlock()
+	assets = newAssets
+	lotNums = newLotNums
+}
+
 func initNVP() (err error) {
 	if err = initPeerClient(); err != nil {
 		appLogger.Debugf("Failed deploying [%s]", err)
@@ -63,6 +107,11 @@ func initNVP() (err error) {
 		return
 	}
 
+	if err = initAuditLog(); err != nil {
+		appLogger.Debugf("Failed opening audit log [%s]", err)
+		return
+	}
+
 	return
 }
 
@@ -87,94 +136,89 @@ func initPeerClient() (err error) {
 	return
 }
 
+// identityRosterPath points at the JSON roster of {alias, enrollID,
+// enrollSecret, mappedName} identities initCryptoClients loads. It used to
+// hardcode charlie/dave/edwina; operators now manage their own population of
+// asset owners by editing this file (and its companion .registered marker).
+func identityRosterPath() string {
+	if path := viper.GetString("identity.roster"); path != "" {
+		return path
+	}
+	return "identities.json"
+}
+
+// initCryptoClients loads the identity roster and resolves only `user` -
+// the identity this running app acts as - up front. Every other alias in
+// the roster (which can grow arbitrarily large) is registered and enrolled
+// lazily, on first use, via identities.ClientFor.
 func initCryptoClients() error {
 	crypto.Init()
 
-	// Initialize the clients mapping charlie, dave, and edwina
-	// to identities already defined in 'membersrvc.yaml'
-
-	// Charlie as diego
-	if err := crypto.RegisterClient("diego", nil, "diego", "DRJ23pEQl16a"); err != nil {
-		return err
-	}
-	var err error
-	charlie, err = crypto.InitClient("diego", nil)
+	store, err := newIdentityStore(identityRosterPath())
 	if err != nil {
 		return err
 	}
+	identities = store
 
-	// Dave as binhn
-	if err := crypto.RegisterClient("binhn", nil, "binhn", "7avZQLwcUe9q"); err != nil {
-		return err
-	}
-	dave, err = crypto.InitClient("binhn", nil)
+	client, cert, err := store.ClientFor(user)
 	if err != nil {
+		appLogger.Errorf("Failed loading identity %s [%s]", user, err)
 		return err
 	}
+	myClient = client
+	myCert = cert
 
-	// Edwina as test_user0
-	if err := crypto.RegisterClient("test_user0", nil, "test_user0", "MS9qrN8hFjlE"); err != nil {
-		return err
-	}
-	edwina, err = crypto.InitClient("test_user0", nil)
-	if err != nil {
-		return err
-	}
+	clients = map[string]crypto.Client{user: client}
+	certs = map[string]crypto.CertificateHandler{user: cert}
 
-	charlieCert, err = charlie.GetEnrollmentCertificateHandler()
-	if err != nil {
-		appLogger.Errorf("Failed getting Charlie ECert [%s]", err)
-		return err
-	}
-
-	daveCert, err = dave.GetEnrollmentCertificateHandler()
-	if err != nil {
-		appLogger.Errorf("Failed getting Dave ECert [%s]", err)
-		return err
-	}
+	return nil
+}
 
-	edwinaCert, err = edwina.GetEnrollmentCertificateHandler()
-	if err != nil {
-		appLogger.Errorf("Failed getting Edwina ECert [%s]", err)
-		return err
+// newAssetProvider builds the assetprovider.AssetProvider selected via
+// assets.provider (file|json|http, defaulting to file), so operators can
+// point the demo at a real inventory system without recompiling.
+func newAssetProvider() (assetprovider.AssetProvider, error) {
+	switch provider := viper.GetString("assets.provider"); provider {
+	case "", "file":
+		path := viper.GetString("assets.file.path")
+		if path == "" {
+			path = "assets.txt"
+		}
+		return assetprovider.NewFileProvider(path), nil
+	case "json":
+		path := viper.GetString("assets.json.path")
+		if path == "" {
+			path = "assets.json"
+		}
+		return assetprovider.NewJSONProvider(path), nil
+	case "http":
+		url := viper.GetString("assets.http.url")
+		if url == "" {
+			return nil, fmt.Errorf("assets.http.url must be set when assets.provider = http")
+		}
+		return assetprovider.NewHTTPProvider(url), nil
+	default:
+		return nil, fmt.Errorf("Unknown assets.provider: %s", provider)
 	}
-
-	clients = map[string]crypto.Client{"charlie": charlie, "dave": dave, "edwina": edwina}
-	certs = map[string]crypto.CertificateHandler{"charlie": charlieCert, "dave": daveCert, "edwina": edwinaCert}
-
-	myClient = clients[user]
-	myCert = certs[user]
-
-	return nil
 }
 
 func readAssets() error {
-	assets = make(map[string]string)
-	lotNums = make([]string, 0, 47)
-
-	file, err := os.Open("assets.txt")
+	provider, err := newAssetProvider()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		assetLine := scanner.Text()
-		assetParts := strings.Split(assetLine, ";")
-
-		lotNum := assetParts[0]
-		assetName := assetParts[1]
-
-		assets[lotNum] = assetName
-		lotNums = append(lotNums, lotNum)
-	}
+	assetProvider = provider
 
-	if err := scanner.Err(); err != nil {
+	loadedAssets, loadedLotNums, err := assetProvider.Load()
+	if err != nil {
 		return err
 	}
+	setInventory(loadedAssets, loadedLotNums)
 
-	return nil
+	return assetProvider.Watch(func(newAssets map[string]string, newLotNums []string) {
+		setInventory(newAssets, newLotNums)
+		appLogger.Debugf("Reloaded %d assets from provider", len(newAssets))
+	})
 }
 
 func processTransaction(tx *pb.Transaction) (*pb.Response, error) {
@@ -214,12 +258,14 @@ func deployInternal(deployer crypto.Client, adminCert crypto.CertificateHandler)
 		return nil, fmt.Errorf("Error deploying chaincode: %s ", err)
 	}
 
-	resp, err = processTransaction(transaction)
+	// Deploy carries the administrator's certificate as Metadata rather than a
+	// sigma signature, so there is no sigma/binding pair to record.
+	resp, err = auditedTransaction(deployer, "deploy", spec.Input.Args, nil, nil, nil, transaction)
 
 	appLogger.Debugf("resp [%s]", resp.String())
 
-	chaincodeName = cds.ChaincodeSpec.ChaincodeID.Name
-	appLogger.Debugf("ChaincodeName [%s]", chaincodeName)
+	setChaincodeName(cds.ChaincodeSpec.ChaincodeID.Name)
+	appLogger.Debugf("ChaincodeName [%s]", getChaincodeName())
 
 	return
 }
@@ -257,7 +303,7 @@ func assignOwnershipInternal(invoker crypto.Client, invokerCert crypto.Certifica
 	// Prepare spec and submit
 	spec := &pb.ChaincodeSpec{
 		Type:                 1,
-		ChaincodeID:          &pb.ChaincodeID{Name: chaincodeName},
+		ChaincodeID:          &pb.ChaincodeID{Name: getChaincodeName()},
 		Input:                chaincodeInput,
 		Metadata:             sigma, // Proof of identity
 		ConfidentialityLevel: confidentialityLevel,
@@ -271,7 +317,7 @@ func assignOwnershipInternal(invoker crypto.Client, invokerCert crypto.Certifica
 		return nil, fmt.Errorf("Error deploying chaincode: %s ", err)
 	}
 
-	return processTransaction(transaction)
+	return auditedTransaction(invoker, "assign", chaincodeInput.Args, chaincodeInputRaw, sigma, binding, transaction)
 }
 
 func transferOwnershipInternal(owner crypto.Client, ownerCert crypto.CertificateHandler, asset string, newOwnerCert crypto.CertificateHandler) (resp *pb.Response, err error) {
@@ -308,7 +354,74 @@ func transferOwnershipInternal(owner crypto.Client, ownerCert crypto.Certificate
 	// Prepare spec and submit
 	spec := &pb.ChaincodeSpec{
 		Type:                 1,
-		ChaincodeID:          &pb.ChaincodeID{Name: chaincodeName},
+		ChaincodeID:          &pb.ChaincodeID{Name: getChaincodeName()},
+		Input:                chaincodeInput,
+		Metadata:             sigma, // Proof of identity
+		ConfidentialityLevel: confidentialityLevel,
+	}
+
+	chaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
+
+	// Now create the Transactions message and send to Peer.
+	transaction, err := txHandler.NewChaincodeExecute(chaincodeInvocationSpec, util.GenerateUUID())
+	if err != nil {
+		return nil, fmt.Errorf("Error deploying chaincode: %s ", err)
+	}
+
+	return auditedTransaction(owner, "transfer", chaincodeInput.Args, chaincodeInputRaw, sigma, binding, transaction)
+
+}
+
+// Transfer describes a single ownership change that is part of a batch
+// transfer request: the asset being moved and the certificate of its
+// new owner.
+type Transfer struct {
+	Asset        string
+	NewOwnerCert crypto.CertificateHandler
+}
+
+func batchTransferInternal(owner crypto.Client, ownerCert crypto.CertificateHandler, transfers []Transfer) (resp *pb.Response, err error) {
+	// Get a transaction handler to be used to submit the execute transaction
+	// and bind the chaincode access control logic using the binding
+
+	submittingCertHandler, err := owner.GetTCertificateHandlerNext()
+	if err != nil {
+		return nil, err
+	}
+	txHandler, err := submittingCertHandler.GetTransactionHandler()
+	if err != nil {
+		return nil, err
+	}
+	binding, err := txHandler.GetBinding()
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, 1+2*len(transfers))
+	args = append(args, "batch_transfer")
+	for _, t := range transfers {
+		args = append(args, t.Asset, base64.StdEncoding.EncodeToString(t.NewOwnerCert.GetCertificate()))
+	}
+
+	chaincodeInput := &pb.ChaincodeInput{Args: util.ToChaincodeArgs(args...)}
+	chaincodeInputRaw, err := proto.Marshal(chaincodeInput)
+	if err != nil {
+		return nil, err
+	}
+
+	// Access control. Owner signs chaincodeInputRaw || binding once to confirm
+	// his identity for the whole batch. The chaincode rejects the entire
+	// batch_transfer action if any single ownership check in it fails, so
+	// this single signature covers an all-or-nothing transaction.
+	sigma, err := ownerCert.Sign(append(chaincodeInputRaw, binding...))
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare spec and submit
+	spec := &pb.ChaincodeSpec{
+		Type:                 1,
+		ChaincodeID:          &pb.ChaincodeID{Name: getChaincodeName()},
 		Input:                chaincodeInput,
 		Metadata:             sigma, // Proof of identity
 		ConfidentialityLevel: confidentialityLevel,
@@ -322,8 +435,36 @@ func transferOwnershipInternal(owner crypto.Client, ownerCert crypto.Certificate
 		return nil, fmt.Errorf("Error deploying chaincode: %s ", err)
 	}
 
-	return processTransaction(transaction)
+	return auditedTransaction(owner, "batch_transfer", chaincodeInput.Args, chaincodeInputRaw, sigma, binding, transaction)
+}
+
+func whoAreTheOwners(invoker crypto.Client, assets []string) (transaction *pb.Transaction, resp *pb.Response, err error) {
+	args := make([]string, 0, 1+len(assets))
+	args = append(args, "batch_query")
+	args = append(args, assets...)
+
+	chaincodeInput := &pb.ChaincodeInput{Args: util.ToChaincodeArgs(args...)}
+
+	// Prepare spec and submit
+	spec := &pb.ChaincodeSpec{
+		Type:                 1,
+		ChaincodeID:          &pb.ChaincodeID{Name: getChaincodeName()},
+		Input:                chaincodeInput,
+		ConfidentialityLevel: confidentialityLevel,
+	}
+
+	chaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}
 
+	// Now create the Transactions message and send to Peer.
+	transaction, err = invoker.NewChaincodeQuery(chaincodeInvocationSpec, util.GenerateUUID())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error deploying chaincode: %s ", err)
+	}
+
+	// The response payload is a JSON-encoded map[string]string of asset -> owner,
+	// decoded by callers the same way a single whoIsTheOwner response is decoded.
+	resp, err = processTransaction(transaction)
+	return
 }
 
 func whoIsTheOwner(invoker crypto.Client, asset string) (transaction *pb.Transaction, resp *pb.Response, err error) {
@@ -332,7 +473,7 @@ func whoIsTheOwner(invoker crypto.Client, asset string) (transaction *pb.Transac
 	// Prepare spec and submit
 	spec := &pb.ChaincodeSpec{
 		Type:                 1,
-		ChaincodeID:          &pb.ChaincodeID{Name: chaincodeName},
+		ChaincodeID:          &pb.ChaincodeID{Name: getChaincodeName()},
 		Input:                chaincodeInput,
 		ConfidentialityLevel: confidentialityLevel,
 	}
@@ -0,0 +1,66 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assetprovider
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// FileProvider reads the demo's original semicolon-delimited assets.txt:
+// one `lotNum;assetName` pair per line.
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider returns a provider that reads path on every Load.
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) Load() (map[string]string, []string, error) {
+	file, err := os.Open(p.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	assets := make(map[string]string)
+	lotNums := make([]string, 0, 47)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		assetParts := strings.Split(scanner.Text(), ";")
+
+		lotNum := assetParts[0]
+		assetName := assetParts[1]
+
+		assets[lotNum] = assetName
+		lotNums = append(lotNums, lotNum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return assets, lotNums, nil
+}
+
+// Watch is a no-op: a plain file read once at startup has nothing to watch.
+func (p *FileProvider) Watch(onChange func(map[string]string, []string)) error {
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assetprovider
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFileProviderLoad(t *testing.T) {
+	f, err := ioutil.TempFile("", "assets")
+	if err != nil {
+		t.Fatalf("TempFile: %s", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("1;gold\n2;silver\n"); err != nil {
+		t.Fatalf("WriteString: %s", err)
+	}
+	f.Close()
+
+	assets, lotNums, err := NewFileProvider(f.Name()).Load()
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+
+	wantAssets := map[string]string{"1": "gold", "2": "silver"}
+	if !reflect.DeepEqual(assets, wantAssets) {
+		t.Fatalf("assets = %v, want %v", assets, wantAssets)
+	}
+	wantLotNums := []string{"1", "2"}
+	if !reflect.DeepEqual(lotNums, wantLotNums) {
+		t.Fatalf("lotNums = %v, want %v", lotNums, wantLotNums)
+	}
+}
+
+func TestFileProviderLoadMissingFile(t *testing.T) {
+	if _, _, err := NewFileProvider("/nonexistent/assets.txt").Load(); err == nil {
+		t.Fatalf("expected an error loading a missing file")
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assetprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPProvider fetches the inventory, encoded the same way as JSONProvider,
+// from a remote inventory system.
+type HTTPProvider struct {
+	url          string
+	client       *http.Client
+	pollInterval time.Duration
+}
+
+// NewHTTPProvider returns a provider that fetches url on every Load, polling
+// it every 30 seconds while Watch runs.
+func NewHTTPProvider(url string) *HTTPProvider {
+	return &HTTPProvider{url: url, client: http.DefaultClient, pollInterval: 30 * time.Second}
+}
+
+func (p *HTTPProvider) Load() (map[string]string, []string, error) {
+	resp, err := p.client.Get(p.url)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("Error fetching assets from %s: status %d", p.url, resp.StatusCode)
+	}
+
+	var entries []Asset
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, nil, err
+	}
+
+	assets := make(map[string]string, len(entries))
+	lotNums := make([]string, 0, len(entries))
+	for _, e := range entries {
+		assets[e.LotNum] = e.Name
+		lotNums = append(lotNums, e.LotNum)
+	}
+
+	return assets, lotNums, nil
+}
+
+// Watch polls the remote inventory every pollInterval, calling onChange each
+// time the fetched result differs from the last one seen.
+func (p *HTTPProvider) Watch(onChange func(map[string]string, []string)) error {
+	lastAssets, _, err := p.Load()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.pollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			assets, lotNums, err := p.Load()
+			if err != nil {
+				continue
+			}
+			if !equalInventory(assets, lastAssets) {
+				lastAssets = assets
+				onChange(assets, lotNums)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func equalInventory(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for lotNum, name := range a {
+		if b[lotNum] != name {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assetprovider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHTTPProviderLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"lotNum":"1","name":"gold"},{"lotNum":"2","name":"silver"}]`)
+	}))
+	defer server.Close()
+
+	assets, lotNums, err := NewHTTPProvider(server.URL).Load()
+	if err != nil {
+		t.Fatalf("Load: %s", err)
+	}
+
+	wantAssets := map[string]string{"1": "gold", "2": "silver"}
+	if !reflect.DeepEqual(assets, wantAssets) {
+		t.Fatalf("assets = %v, want %v", assets, wantAssets)
+	}
+	wantLotNums := []string{"1", "2"}
+	if !reflect.DeepEqual(lotNums, wantLotNums) {
+		t.Fatalf("lotNums = %v, want %v", lotNums, wantLotNums)
+	}
+}
+
+func TestHTTPProviderLoadNonOKStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if _, _, err := NewHTTPProvider(server.URL).Load(); err == nil {
+		t.Fatalf("expected an error for a non-200 response")
+	}
+}
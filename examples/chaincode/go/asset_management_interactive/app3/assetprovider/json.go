@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package assetprovider
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// Asset is one inventory entry: a lot number and the asset name it holds.
+type Asset struct {
+	LotNum string `json:"lotNum"`
+	Name   string `json:"name"`
+}
+
+// JSONProvider reads the inventory as a JSON array of Asset, an alternative
+// to the original assets.txt for operators who'd rather manage the
+// inventory as structured data.
+type JSONProvider struct {
+	path string
+}
+
+// NewJSONProvider returns a provider that reads the JSON asset list at path
+// on every Load.
+func NewJSONProvider(path string) *JSONProvider {
+	return &JSONProvider{path: path}
+}
+
+func (p *JSONProvider) Load() (map[string]string, []string, error) {
+	raw, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []Asset
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, nil, err
+	}
+
+	assets := make(map[string]string, len(entries))
+	lotNums := make([]string, 0, len(entries))
+	for _, e := range entries {
+		assets[e.LotNum] = e.Name
+		lotNums = append(lotNums, e.LotNum)
+	}
+
+	return assets, lotNums, nil
+}
+
+// Watch is a no-op: a plain file read once at startup has nothing to watch.
+func (p *JSONProvider) Watch(onChange func(map[string]string, []string)) error {
+	return nil
+}
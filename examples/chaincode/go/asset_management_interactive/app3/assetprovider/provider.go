@@ -0,0 +1,33 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package assetprovider supplies the asset_management_interactive demo's
+// inventory (lot number -> asset name), so operators can point it at a real
+// inventory system, instead of the checked-in assets.txt, without
+// recompiling.
+package assetprovider
+
+// AssetProvider loads the demo's inventory: a map of lot number to asset
+// name, and the lot numbers in display order.
+type AssetProvider interface {
+	Load() (assets map[string]string, lotNums []string, err error)
+
+	// Watch calls onChange whenever the underlying inventory changes, so a
+	// long-running process (e.g. the httpapi front-end) can pick up updates
+	// without restarting. Providers for which that doesn't apply (e.g. a
+	// static file read once at startup) return nil immediately.
+	Watch(onChange func(assets map[string]string, lotNums []string)) error
+}
@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/crypto"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+
+	"github.com/hyperledger/fabric/examples/chaincode/go/asset_management_interactive/app3/auditlog"
+)
+
+var auditLog *auditlog.Log
+
+// statusPending is the sentinel Status recorded for a transaction that has
+// been submitted but not yet answered by the peer; see auditedTransaction.
+const statusPending = -2
+
+// auditLogPath points at the tamper-evident audit log every internal helper
+// writes to. `fabric-audit verify` walks this same file offline.
+func auditLogPath() string {
+	if path := viper.GetString("audit.log.path"); path != "" {
+		return path
+	}
+	return "audit.log"
+}
+
+func initAuditLog() error {
+	log, err := auditlog.Open(auditLogPath())
+	if err != nil {
+		return err
+	}
+	auditLog = log
+	return nil
+}
+
+// aliasFor returns the alias the given client was loaded under in
+// identities, so audit records can name the invoker the same way
+// identityStore does rather than an opaque crypto.Client value.
+func aliasFor(client crypto.Client) string {
+	if alias, ok := identities.AliasFor(client); ok {
+		return alias
+	}
+	return "unknown"
+}
+
+// auditedTransaction submits tx the same way processTransaction does, but
+// first writes a pre-submission record - invoker, chaincode function and
+// arguments, sigma, and tx binding, with Status statusPending - so a client
+// that crashes or hangs waiting on the peer still leaves a durable record
+// that the transaction was submitted. Once the peer answers (or the call
+// fails), a follow-up record under the same Id is appended with the final
+// status, chaining onto the previous record in auditLog.
+func auditedTransaction(invoker crypto.Client, function string, args [][]byte, payload, sigma, binding []byte, tx *pb.Transaction) (*pb.Response, error) {
+	id := util.GenerateUUID()
+	alias := aliasFor(invoker)
+	argStrs := argsToStrings(args)
+
+	if _, auditErr := auditLog.Append(id, alias, function, argStrs, payload, sigma, binding, statusPending, time.Now().UnixNano()); auditErr != nil {
+		appLogger.Errorf("Failed writing pre-submission audit record for %s: %s", function, auditErr)
+	}
+
+	resp, err := processTransaction(tx)
+
+	status := int32(-1)
+	if resp != nil {
+		status = int32(resp.Status)
+	}
+	if _, auditErr := auditLog.Append(id, alias, function, argStrs, payload, sigma, binding, status, time.Now().UnixNano()); auditErr != nil {
+		appLogger.Errorf("Failed writing audit record for %s: %s", function, auditErr)
+	}
+
+	return resp, err
+}
+
+// argsToStrings renders chaincode args (raw [][]byte, per pb.ChaincodeInput)
+// as strings for the audit record.
+func argsToStrings(args [][]byte) []string {
+	strs := make([]string, len(args))
+	for i, a := range args {
+		strs[i] = string(a)
+	}
+	return strs
+}
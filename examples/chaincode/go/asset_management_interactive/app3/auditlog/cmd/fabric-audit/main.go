@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command fabric-audit walks an asset_management_interactive audit log
+// offline, checking that it hasn't been tampered with.
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hyperledger/fabric/examples/chaincode/go/asset_management_interactive/app3/auditlog"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "verify":
+		verifyCmd(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: fabric-audit verify -log <path> [-certs <path>]")
+}
+
+// certRoster is the content of the -certs file: a JSON map of invoker alias
+// to base64-encoded DER certificate.
+type certRoster map[string]string
+
+func verifyCmd(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	logPath := fs.String("log", "audit.log", "path to the audit log to verify")
+	certsPath := fs.String("certs", "", "optional path to a JSON {alias: base64-cert} roster used to verify sigma against the invoker's certificate")
+	fs.Parse(args)
+
+	var resolveCert auditlog.CertResolver
+	if *certsPath != "" {
+		roster, err := loadCertRoster(*certsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fabric-audit: %s\n", err)
+			os.Exit(2)
+		}
+		resolveCert = func(invoker string) ([]byte, bool) {
+			encoded, ok := roster[invoker]
+			if !ok {
+				return nil, false
+			}
+			der, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, false
+			}
+			return der, true
+		}
+	}
+
+	file, err := os.Open(*logPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fabric-audit: %s\n", err)
+		os.Exit(2)
+	}
+	defer file.Close()
+
+	violations, err := auditlog.Verify(file, resolveCert)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fabric-audit: %s\n", err)
+		os.Exit(2)
+	}
+	if len(violations) == 0 {
+		fmt.Println("OK: audit log is intact")
+		return
+	}
+
+	for _, v := range violations {
+		fmt.Fprintln(os.Stderr, v.Error())
+	}
+	fmt.Fprintf(os.Stderr, "FAIL: %d violation(s) found\n", len(violations))
+	os.Exit(1)
+}
+
+func loadCertRoster(path string) (certRoster, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading cert roster %s: %s", path, err)
+	}
+	var roster certRoster
+	if err := json.Unmarshal(raw, &roster); err != nil {
+		return nil, fmt.Errorf("Error parsing cert roster %s: %s", path, err)
+	}
+	return roster, nil
+}
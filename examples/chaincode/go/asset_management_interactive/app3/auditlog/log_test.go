@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tempLogPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "auditlog")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	return filepath.Join(dir, "audit.log"), func() { os.RemoveAll(dir) }
+}
+
+func TestAppendChainsPrevHash(t *testing.T) {
+	path, cleanup := tempLogPath(t)
+	defer cleanup()
+
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+
+	first, err := log.Append("id-1", "alice", "deploy", nil, nil, nil, nil, 200, 1)
+	if err != nil {
+		t.Fatalf("Append: %s", err)
+	}
+	second, err := log.Append("id-2", "alice", "assign", nil, nil, nil, nil, 200, 2)
+	if err != nil {
+		t.Fatalf("Append: %s", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	if string(second.PrevHash) != string(hashRecord(first)) {
+		t.Fatalf("second record did not chain onto the first")
+	}
+}
+
+func TestOpenResumesChainAcrossRestarts(t *testing.T) {
+	path, cleanup := tempLogPath(t)
+	defer cleanup()
+
+	log, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	if _, err := log.Append("id-1", "alice", "deploy", nil, nil, nil, nil, 200, 1); err != nil {
+		t.Fatalf("Append: %s", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open (reopen): %s", err)
+	}
+	defer reopened.Close()
+
+	second, err := reopened.Append("id-2", "alice", "assign", nil, nil, nil, nil, 200, 2)
+	if err != nil {
+		t.Fatalf("Append: %s", err)
+	}
+
+	var records []*Record
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open for read: %s", err)
+	}
+	defer f.Close()
+	if err := ReadAll(f, func(r *Record) error {
+		records = append(records, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records after reopening, got %d", len(records))
+	}
+	if string(second.PrevHash) != string(hashRecord(records[0])) {
+		t.Fatalf("record appended after reopening did not chain onto the persisted record")
+	}
+}
@@ -0,0 +1,37 @@
+// Code generated by protoc-gen-go.
+// source: record.proto
+// DO NOT EDIT!
+
+package auditlog
+
+import proto "github.com/golang/protobuf/proto"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = math.Inf
+
+// Record is one entry of the signed audit log: the invoker, the chaincode
+// function and arguments it submitted, the sigma signature and tx binding
+// that accompanied the transaction, the peer's response status, and the
+// SHA-256 of the previous record in the file, chaining the log together.
+type Record struct {
+	Id          string   `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	TimestampNs int64    `protobuf:"varint,2,opt,name=timestamp_ns,json=timestampNs" json:"timestamp_ns,omitempty"`
+	Invoker     string   `protobuf:"bytes,3,opt,name=invoker" json:"invoker,omitempty"`
+	Function    string   `protobuf:"bytes,4,opt,name=function" json:"function,omitempty"`
+	Args        []string `protobuf:"bytes,5,rep,name=args" json:"args,omitempty"`
+	Payload     []byte   `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`
+	Sigma       []byte   `protobuf:"bytes,7,opt,name=sigma,proto3" json:"sigma,omitempty"`
+	Binding     []byte   `protobuf:"bytes,8,opt,name=binding,proto3" json:"binding,omitempty"`
+	Status      int32    `protobuf:"varint,9,opt,name=status" json:"status,omitempty"`
+	PrevHash    []byte   `protobuf:"bytes,10,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+}
+
+func (m *Record) Reset()         { *m = Record{} }
+func (m *Record) String() string { return proto.CompactTextString(m) }
+func (*Record) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterType((*Record)(nil), "auditlog.Record")
+}
@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditlog
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// CertResolver maps the invoker alias recorded in a Record back to the
+// enrollment certificate that was used to sign it, so verification can run
+// entirely offline against the client's own records (e.g. backed by the
+// identityStore's certificate cache).
+type CertResolver func(invoker string) (certDER []byte, ok bool)
+
+// Violation describes one record that failed verification.
+type Violation struct {
+	Index   int
+	Record  *Record
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("record %d (%s): %s", v.Index, v.Record.Id, v.Message)
+}
+
+// Verify walks every record in r, checking that each one's PrevHash chains
+// to the record before it (tamper-evidence) and, where resolveCert can find
+// the invoker's certificate, that Sigma is a valid signature over
+// Payload||Binding (mirroring the sigma check TransactionPreValidation
+// performs server-side). Records with no Sigma (e.g. "deploy", which carries
+// the administrator's certificate as Metadata instead of a sigma/binding
+// pair) are never signed and are skipped rather than flagged. It returns
+// every violation found, in record order.
+func Verify(r io.Reader, resolveCert CertResolver) ([]*Violation, error) {
+	var violations []*Violation
+	expectedPrevHash := make([]byte, sha256.Size)
+	index := 0
+
+	err := ReadAll(r, func(record *Record) error {
+		if !bytes.Equal(record.PrevHash, expectedPrevHash) {
+			violations = append(violations, &Violation{
+				Index: index, Record: record,
+				Message: "prev_hash does not chain to the preceding record",
+			})
+		}
+
+		if resolveCert != nil && len(record.Sigma) > 0 {
+			if certDER, ok := resolveCert(record.Invoker); ok {
+				if err := verifySigma(record, certDER); err != nil {
+					violations = append(violations, &Violation{
+						Index: index, Record: record,
+						Message: fmt.Sprintf("sigma does not verify against %s's certificate: %s", record.Invoker, err),
+					})
+				}
+			}
+		}
+
+		expectedPrevHash = hashRecord(record)
+		index++
+		return nil
+	})
+	if err != nil {
+		return violations, err
+	}
+
+	return violations, nil
+}
+
+func verifySigma(record *Record, certDER []byte) error {
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %s", err)
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("certificate does not carry an ECDSA public key")
+	}
+
+	digest := sha256.Sum256(append(append([]byte{}, record.Payload...), record.Binding...))
+
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(record.Sigma, &sig); err != nil {
+		return fmt.Errorf("invalid sigma encoding: %s", err)
+	}
+	if !ecdsa.Verify(pub, digest[:], sig.R, sig.S) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
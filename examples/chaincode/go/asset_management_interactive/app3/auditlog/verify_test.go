@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditlog
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+)
+
+// signingCert generates a throwaway ECDSA key/cert pair and returns its DER
+// encoding alongside a sign function, so tests can produce Sigma values that
+// verifySigma will accept.
+func signingCert(t *testing.T) (certDER []byte, sign func(digest []byte) []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %s", err)
+	}
+
+	return der, func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			t.Fatalf("Sign: %s", err)
+		}
+		sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		if err != nil {
+			t.Fatalf("Marshal sigma: %s", err)
+		}
+		return sig
+	}
+}
+
+func writeRecords(t *testing.T, records ...*Record) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	for _, r := range records {
+		if err := writeRecord(buf, r); err != nil {
+			t.Fatalf("writeRecord: %s", err)
+		}
+	}
+	return buf
+}
+
+func TestVerifyDetectsBrokenChain(t *testing.T) {
+	first := &Record{Id: "id-1", Invoker: "alice", Function: "deploy", PrevHash: make([]byte, sha256.Size)}
+	second := &Record{Id: "id-2", Invoker: "alice", Function: "assign", PrevHash: []byte("not the real hash")}
+
+	violations, err := Verify(writeRecords(t, first, second), nil)
+	if err != nil {
+		t.Fatalf("Verify: %s", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d: %v", len(violations), violations)
+	}
+	if violations[0].Index != 1 {
+		t.Fatalf("expected the violation on the second record, got index %d", violations[0].Index)
+	}
+}
+
+func TestVerifyAcceptsIntactChain(t *testing.T) {
+	first := &Record{Id: "id-1", Invoker: "alice", Function: "deploy", PrevHash: make([]byte, sha256.Size)}
+	second := &Record{Id: "id-2", Invoker: "alice", Function: "assign", PrevHash: hashRecord(first)}
+
+	violations, err := Verify(writeRecords(t, first, second), nil)
+	if err != nil {
+		t.Fatalf("Verify: %s", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+}
+
+func TestVerifySkipsUnsignedRecords(t *testing.T) {
+	// A "deploy" record carries the administrator's certificate as Metadata
+	// rather than a sigma/binding pair, so Sigma is nil - that must not be
+	// flagged as a forged or missing signature.
+	deploy := &Record{Id: "id-1", Invoker: "alice", Function: "deploy", PrevHash: make([]byte, sha256.Size)}
+
+	resolveCert := func(invoker string) ([]byte, bool) {
+		t.Fatalf("resolveCert should not be called for an unsigned record")
+		return nil, false
+	}
+
+	violations, err := Verify(writeRecords(t, deploy), resolveCert)
+	if err != nil {
+		t.Fatalf("Verify: %s", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations for an unsigned record, got %v", violations)
+	}
+}
+
+func TestVerifyCatchesBadSigma(t *testing.T) {
+	certDER, sign := signingCert(t)
+
+	payload, binding := []byte("payload"), []byte("binding")
+	digest := sha256.Sum256(append(append([]byte{}, payload...), binding...))
+
+	record := &Record{
+		Id: "id-1", Invoker: "alice", Function: "assign",
+		Payload: payload, Binding: binding, Sigma: sign(digest[:]),
+		PrevHash: make([]byte, sha256.Size),
+	}
+
+	resolveCert := func(invoker string) ([]byte, bool) { return certDER, true }
+
+	violations, err := Verify(writeRecords(t, record), resolveCert)
+	if err != nil {
+		t.Fatalf("Verify: %s", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("expected a genuine signature to verify, got %v", violations)
+	}
+
+	// Now tamper with the payload after signing: sigma no longer matches.
+	record.Payload = []byte("tampered")
+	violations, err = Verify(writeRecords(t, record), resolveCert)
+	if err != nil {
+		t.Fatalf("Verify: %s", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation for a tampered payload, got %d: %v", len(violations), violations)
+	}
+}
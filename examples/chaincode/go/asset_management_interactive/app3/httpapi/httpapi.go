@@ -0,0 +1,183 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpapi exposes the asset_management_interactive demo over a
+// JSON/HTTP front-end, so that services which cannot link the Go client
+// directly can still deploy, assign, transfer and query assets.
+package httpapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/crypto"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+// AssetService is the subset of the asset_management_interactive app that
+// the HTTP front-end drives. The app3 package implements it on top of its
+// existing deployInternal/assignOwnershipInternal/transferOwnershipInternal/
+// whoIsTheOwner helpers.
+type AssetService interface {
+	Deploy(deployer crypto.Client, adminCert crypto.CertificateHandler) (*pb.Response, error)
+	Assign(invoker crypto.Client, invokerCert crypto.CertificateHandler, asset string, newOwnerCert crypto.CertificateHandler) (*pb.Response, error)
+	Transfer(owner crypto.Client, ownerCert crypto.CertificateHandler, asset string, newOwnerCert crypto.CertificateHandler) (*pb.Response, error)
+	Owner(invoker crypto.Client, asset string) (*pb.Response, error)
+}
+
+// Authenticator maps an HTTP bearer token to one of the app's registered
+// identities. Implementations are expected to resolve the token's alias
+// through the same identityStore the rest of the app uses.
+type Authenticator interface {
+	Authenticate(token string) (crypto.Client, crypto.CertificateHandler, bool)
+}
+
+// Server is a small JSON front-end over an AssetService.
+type Server struct {
+	service AssetService
+	auth    Authenticator
+	mux     *http.ServeMux
+}
+
+// NewServer wires up routes for deploy/assign/transfer/query against service,
+// authenticating every request through auth.
+func NewServer(service AssetService, auth Authenticator) *Server {
+	s := &Server{service: service, auth: auth, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/assets/deploy", s.handleDeploy)
+	s.mux.HandleFunc("/assets/", s.handleAsset)
+	return s
+}
+
+// ListenAndServe starts the HTTP server on the port configured under
+// httpapi.port (default 8080).
+func (s *Server) ListenAndServe() error {
+	port := viper.GetInt("httpapi.port")
+	if port == 0 {
+		port = 8080
+	}
+	return http.ListenAndServe(fmt.Sprintf(":%d", port), s.mux)
+}
+
+// ownershipRequest is the body of POST /assets/{lot}/assign and
+// POST /assets/{lot}/transfer. The invoker is taken from the request's
+// bearer token, not from the body, so this carries only the new owner's
+// certificate.
+type ownershipRequest struct {
+	NewOwnerCert string `json:"new_owner_cert"`
+}
+
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (crypto.Client, crypto.CertificateHandler, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	invoker, invokerCert, ok := s.auth.Authenticate(token)
+	if !ok {
+		http.Error(w, "unknown identity", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+	return invoker, invokerCert, true
+}
+
+func decodeCert(invoker crypto.Client, encoded string) (crypto.CertificateHandler, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid new_owner_cert: %s", err)
+	}
+	return invoker.GetTCertificateHandlerFromDER(raw)
+}
+
+func writeResponse(w http.ResponseWriter, resp *pb.Response, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  resp.Status.String(),
+		"message": string(resp.Msg),
+	})
+}
+
+func (s *Server) handleDeploy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	invoker, invokerCert, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+	resp, err := s.service.Deploy(invoker, invokerCert)
+	writeResponse(w, resp, err)
+}
+
+// handleAsset dispatches POST /assets/{lot}/assign, POST /assets/{lot}/transfer
+// and GET /assets/{lot}/owner.
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/assets/"), "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	lot, action := parts[0], parts[1]
+
+	invoker, invokerCert, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	switch action {
+	case "assign":
+		s.handleOwnershipChange(w, r, lot, invoker, invokerCert, s.service.Assign)
+	case "transfer":
+		s.handleOwnershipChange(w, r, lot, invoker, invokerCert, s.service.Transfer)
+	case "owner":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		resp, err := s.service.Owner(invoker, lot)
+		writeResponse(w, resp, err)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleOwnershipChange(w http.ResponseWriter, r *http.Request, lot string, invoker crypto.Client, invokerCert crypto.CertificateHandler,
+	do func(crypto.Client, crypto.CertificateHandler, string, crypto.CertificateHandler) (*pb.Response, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ownershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+		return
+	}
+	newOwnerCert, err := decodeCert(invoker, req.NewOwnerCert)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := do(invoker, invokerCert, lot, newOwnerCert)
+	writeResponse(w, resp, err)
+}
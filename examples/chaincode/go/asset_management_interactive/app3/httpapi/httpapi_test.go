@@ -0,0 +1,166 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/crypto"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// fakeAssetService records which method was called and with what asset, so
+// tests can assert on routing without a real crypto.Client.
+type fakeAssetService struct {
+	called string
+	asset  string
+}
+
+func (f *fakeAssetService) Deploy(deployer crypto.Client, adminCert crypto.CertificateHandler) (*pb.Response, error) {
+	f.called = "deploy"
+	return &pb.Response{Status: pb.Response_SUCCESS}, nil
+}
+
+func (f *fakeAssetService) Assign(invoker crypto.Client, invokerCert crypto.CertificateHandler, asset string, newOwnerCert crypto.CertificateHandler) (*pb.Response, error) {
+	f.called, f.asset = "assign", asset
+	return &pb.Response{Status: pb.Response_SUCCESS}, nil
+}
+
+func (f *fakeAssetService) Transfer(owner crypto.Client, ownerCert crypto.CertificateHandler, asset string, newOwnerCert crypto.CertificateHandler) (*pb.Response, error) {
+	f.called, f.asset = "transfer", asset
+	return &pb.Response{Status: pb.Response_SUCCESS}, nil
+}
+
+func (f *fakeAssetService) Owner(invoker crypto.Client, asset string) (*pb.Response, error) {
+	f.called, f.asset = "owner", asset
+	return &pb.Response{Status: pb.Response_SUCCESS}, nil
+}
+
+// fakeAuthenticator accepts a single known token and rejects everything else.
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Authenticate(token string) (crypto.Client, crypto.CertificateHandler, bool) {
+	if token != "good-token" {
+		return nil, nil, false
+	}
+	return nil, nil, true
+}
+
+func TestHandleAssetMissingToken(t *testing.T) {
+	service := &fakeAssetService{}
+	server := NewServer(service, fakeAuthenticator{})
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/lot1/owner", nil)
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if service.called != "" {
+		t.Fatalf("service.%s was called despite missing auth", service.called)
+	}
+}
+
+func TestHandleAssetUnknownToken(t *testing.T) {
+	service := &fakeAssetService{}
+	server := NewServer(service, fakeAuthenticator{})
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/lot1/owner", nil)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if service.called != "" {
+		t.Fatalf("service.%s was called despite an unknown token", service.called)
+	}
+}
+
+func TestHandleAssetMalformedPath(t *testing.T) {
+	server := NewServer(&fakeAssetService{}, fakeAuthenticator{})
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/lot1", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleAssetUnknownAction(t *testing.T) {
+	server := NewServer(&fakeAssetService{}, fakeAuthenticator{})
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/lot1/frobnicate", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleAssetOwnerDispatch(t *testing.T) {
+	service := &fakeAssetService{}
+	server := NewServer(service, fakeAuthenticator{})
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/lot1/owner", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if service.called != "owner" || service.asset != "lot1" {
+		t.Fatalf("service called = %q/%q, want owner/lot1", service.called, service.asset)
+	}
+}
+
+func TestHandleAssetAssignRejectsInvalidCert(t *testing.T) {
+	service := &fakeAssetService{}
+	server := NewServer(service, fakeAuthenticator{})
+
+	body := strings.NewReader(`{"new_owner_cert":"not-base64!"}`)
+	req := httptest.NewRequest(http.MethodPost, "/assets/lot1/assign", body)
+	req.Header.Set("Authorization", "Bearer good-token")
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	// The fake owner cert isn't valid base64, so the request should be
+	// rejected before the service is ever called.
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if service.called != "" {
+		t.Fatalf("service.%s was called despite an invalid new_owner_cert", service.called)
+	}
+}
+
+func TestDecodeCertInvalidBase64(t *testing.T) {
+	if _, err := decodeCert(nil, "not valid base64!"); err == nil {
+		t.Fatalf("expected an error decoding invalid base64")
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"github.com/hyperledger/fabric/core/crypto"
+	pb "github.com/hyperledger/fabric/protos"
+
+	"github.com/hyperledger/fabric/examples/chaincode/go/asset_management_interactive/app3/httpapi"
+)
+
+// nvpAssetService adapts the package's deploy/assign/transfer/query helpers
+// to httpapi.AssetService.
+type nvpAssetService struct{}
+
+func (nvpAssetService) Deploy(deployer crypto.Client, adminCert crypto.CertificateHandler) (*pb.Response, error) {
+	return deployInternal(deployer, adminCert)
+}
+
+func (nvpAssetService) Assign(invoker crypto.Client, invokerCert crypto.CertificateHandler, asset string, newOwnerCert crypto.CertificateHandler) (*pb.Response, error) {
+	return assignOwnershipInternal(invoker, invokerCert, asset, newOwnerCert)
+}
+
+func (nvpAssetService) Transfer(owner crypto.Client, ownerCert crypto.CertificateHandler, asset string, newOwnerCert crypto.CertificateHandler) (*pb.Response, error) {
+	return transferOwnershipInternal(owner, ownerCert, asset, newOwnerCert)
+}
+
+func (nvpAssetService) Owner(invoker crypto.Client, asset string) (*pb.Response, error) {
+	_, resp, err := whoIsTheOwner(invoker, asset)
+	return resp, err
+}
+
+// tokenAuthenticator maps bearer tokens to one of the roster's identities,
+// resolving the backing crypto.Client lazily through identities.ClientFor
+// rather than requiring the alias to already have been loaded.
+type tokenAuthenticator struct {
+	tokens map[string]string // bearer token -> roster alias
+}
+
+func (a tokenAuthenticator) Authenticate(token string) (crypto.Client, crypto.CertificateHandler, bool) {
+	alias, ok := a.tokens[token]
+	if !ok {
+		return nil, nil, false
+	}
+	client, cert, err := identities.ClientFor(alias)
+	if err != nil {
+		return nil, nil, false
+	}
+	return client, cert, true
+}
+
+// startHTTPAPI starts the JSON/HTTP front-end described by httpapi.Server,
+// mapping the given bearer tokens to registered identities. It blocks for
+// the lifetime of the server, so callers typically run it in a goroutine.
+func startHTTPAPI(tokens map[string]string) error {
+	server := httpapi.NewServer(nvpAssetService{}, tokenAuthenticator{tokens: tokens})
+	return server.ListenAndServe()
+}
@@ -0,0 +1,216 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/crypto"
+)
+
+// identity is one entry of the roster file: an alias this app refers to the
+// owner by, the enrollment credentials it was issued by membersrvc, and the
+// name the running app maps that alias to (charlie, dave, edwina, ...).
+type identity struct {
+	Alias        string `json:"alias"`
+	EnrollID     string `json:"enrollID"`
+	EnrollSecret string `json:"enrollSecret"`
+	MappedName   string `json:"mappedName"`
+}
+
+// identities is the identityStore initCryptoClients loads the roster into;
+// exposed package-wide for ClientFor/AliasFor/AddIdentity/RemoveIdentity/
+// ListIdentities callers such as the httpapi front-end.
+var identities *identityStore
+
+// identityStore replaces the hardcoded charlie/dave/edwina population with an
+// arbitrary roster of identities, read once from a local roster file. It only
+// calls crypto.RegisterClient the first time an identity is seen; subsequent
+// starts reuse the enrollment that membersrvc already issued, and crypto.Client
+// / certificate handlers are loaded lazily, on first use.
+type identityStore struct {
+	rosterPath string
+	markerPath string
+
+	mu         sync.Mutex
+	roster     map[string]identity
+	registered map[string]bool
+	clients    map[string]crypto.Client
+	certs      map[string]crypto.CertificateHandler
+}
+
+// newIdentityStore loads the roster at rosterPath (a JSON array of identity
+// entries) and the registration marker alongside it (rosterPath + ".registered").
+func newIdentityStore(rosterPath string) (*identityStore, error) {
+	s := &identityStore{
+		rosterPath: rosterPath,
+		markerPath: rosterPath + ".registered",
+		roster:     make(map[string]identity),
+		registered: make(map[string]bool),
+		clients:    make(map[string]crypto.Client),
+		certs:      make(map[string]crypto.CertificateHandler),
+	}
+
+	raw, err := ioutil.ReadFile(rosterPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading identity roster %s: %s", rosterPath, err)
+	}
+	var entries []identity
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("Error parsing identity roster %s: %s", rosterPath, err)
+	}
+	for _, e := range entries {
+		s.roster[e.Alias] = e
+	}
+
+	if marker, err := ioutil.ReadFile(s.markerPath); err == nil {
+		var aliases []string
+		if err := json.Unmarshal(marker, &aliases); err == nil {
+			for _, alias := range aliases {
+				s.registered[alias] = true
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Error reading registration marker %s: %s", s.markerPath, err)
+	}
+
+	return s, nil
+}
+
+// ClientFor lazily registers (on first run only) and loads the crypto.Client
+// and enrollment certificate for alias, caching both for subsequent calls.
+func (s *identityStore) ClientFor(alias string) (crypto.Client, crypto.CertificateHandler, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if client, ok := s.clients[alias]; ok {
+		return client, s.certs[alias], nil
+	}
+
+	id, ok := s.roster[alias]
+	if !ok {
+		return nil, nil, fmt.Errorf("Unknown identity alias: %s", alias)
+	}
+
+	if !s.registered[alias] {
+		if err := crypto.RegisterClient(id.MappedName, nil, id.EnrollID, id.EnrollSecret); err != nil {
+			return nil, nil, err
+		}
+		s.registered[alias] = true
+		if err := s.persistMarker(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	client, err := crypto.InitClient(id.MappedName, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := client.GetEnrollmentCertificateHandler()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed getting ECert for %s: %s", alias, err)
+	}
+
+	s.clients[alias] = client
+	s.certs[alias] = cert
+	return client, cert, nil
+}
+
+// AliasFor returns the alias client was loaded under, if it has been
+// resolved via ClientFor. It only searches identities that have actually
+// been loaded, so - unlike ListIdentities - it stays cheap no matter how
+// large the roster grows.
+func (s *identityStore) AliasFor(client crypto.Client) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for alias, c := range s.clients {
+		if c == client {
+			return alias, true
+		}
+	}
+	return "", false
+}
+
+// AddIdentity appends a new alias to the roster and persists it, without
+// registering it with membersrvc until it is first used via ClientFor.
+func (s *identityStore) AddIdentity(id identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.roster[id.Alias]; exists {
+		return fmt.Errorf("Identity alias %s already exists", id.Alias)
+	}
+	s.roster[id.Alias] = id
+	return s.persistRoster()
+}
+
+// RemoveIdentity drops alias from the roster and from the in-memory caches.
+// It does not attempt to deregister the identity with membersrvc.
+func (s *identityStore) RemoveIdentity(alias string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.roster[alias]; !exists {
+		return fmt.Errorf("Unknown identity alias: %s", alias)
+	}
+	delete(s.roster, alias)
+	delete(s.registered, alias)
+	delete(s.clients, alias)
+	delete(s.certs, alias)
+	return s.persistRoster()
+}
+
+// ListIdentities returns the current roster, in no particular order.
+func (s *identityStore) ListIdentities() []identity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]identity, 0, len(s.roster))
+	for _, id := range s.roster {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (s *identityStore) persistRoster() error {
+	entries := make([]identity, 0, len(s.roster))
+	for _, id := range s.roster {
+		entries = append(entries, id)
+	}
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.rosterPath, raw, 0644)
+}
+
+func (s *identityStore) persistMarker() error {
+	aliases := make([]string, 0, len(s.registered))
+	for alias := range s.registered {
+		aliases = append(aliases, alias)
+	}
+	raw, err := json.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.markerPath, raw, 0644)
+}
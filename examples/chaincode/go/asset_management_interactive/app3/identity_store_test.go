@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeRoster(t *testing.T, dir string, entries string) string {
+	t.Helper()
+	path := filepath.Join(dir, "roster.json")
+	if err := ioutil.WriteFile(path, []byte(entries), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	return path
+}
+
+func TestNewIdentityStoreLoadsRoster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identitystore")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeRoster(t, dir, `[{"alias":"charlie","enrollID":"user1","enrollSecret":"secret1","mappedName":"charlie"}]`)
+
+	s, err := newIdentityStore(path)
+	if err != nil {
+		t.Fatalf("newIdentityStore: %s", err)
+	}
+
+	ids := s.ListIdentities()
+	if len(ids) != 1 || ids[0].Alias != "charlie" {
+		t.Fatalf("ListIdentities = %v, want a single charlie entry", ids)
+	}
+}
+
+func TestAddAndRemoveIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identitystore")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeRoster(t, dir, `[]`)
+	s, err := newIdentityStore(path)
+	if err != nil {
+		t.Fatalf("newIdentityStore: %s", err)
+	}
+
+	if err := s.AddIdentity(identity{Alias: "dave", EnrollID: "user2", EnrollSecret: "secret2", MappedName: "dave"}); err != nil {
+		t.Fatalf("AddIdentity: %s", err)
+	}
+	if err := s.AddIdentity(identity{Alias: "dave"}); err == nil {
+		t.Fatalf("expected an error adding a duplicate alias")
+	}
+
+	reopened, err := newIdentityStore(path)
+	if err != nil {
+		t.Fatalf("newIdentityStore (reopen): %s", err)
+	}
+	ids := reopened.ListIdentities()
+	if len(ids) != 1 || ids[0].Alias != "dave" {
+		t.Fatalf("ListIdentities after reopen = %v, want a single dave entry", ids)
+	}
+
+	if err := s.RemoveIdentity("dave"); err != nil {
+		t.Fatalf("RemoveIdentity: %s", err)
+	}
+	if err := s.RemoveIdentity("dave"); err == nil {
+		t.Fatalf("expected an error removing an unknown alias")
+	}
+
+	reopened, err = newIdentityStore(path)
+	if err != nil {
+		t.Fatalf("newIdentityStore (reopen after remove): %s", err)
+	}
+	if ids := reopened.ListIdentities(); len(ids) != 0 {
+		t.Fatalf("ListIdentities after remove = %v, want none", ids)
+	}
+}
+
+func TestRegistrationMarkerPersistsAcrossReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identitystore")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeRoster(t, dir, `[{"alias":"charlie","enrollID":"user1","enrollSecret":"secret1","mappedName":"charlie"}]`)
+	s, err := newIdentityStore(path)
+	if err != nil {
+		t.Fatalf("newIdentityStore: %s", err)
+	}
+
+	// Record the registration the way ClientFor would, without going
+	// through crypto.RegisterClient.
+	s.registered["charlie"] = true
+	if err := s.persistMarker(); err != nil {
+		t.Fatalf("persistMarker: %s", err)
+	}
+
+	reopened, err := newIdentityStore(path)
+	if err != nil {
+		t.Fatalf("newIdentityStore (reopen): %s", err)
+	}
+	if !reopened.registered["charlie"] {
+		t.Fatalf("registration marker for charlie did not survive a reopen")
+	}
+}